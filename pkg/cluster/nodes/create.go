@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/container/runtime"
+)
+
+// CreateOptions holds the arguments passed straight through to the
+// container runtime's Create, e.g. mounts, port bindings, and the image to
+// run, equivalent to the flags a hand-written `docker create`/`podman
+// create` invocation would need
+type CreateOptions struct {
+	// Image is the node image to create the container from, e.g.
+	// "kindest/node:v1.27.3"
+	Image string
+	// ExtraArgs are appended to the runtime's create invocation as-is,
+	// e.g. []string{"--privileged", "--tmpfs", "/tmp"}
+	ExtraArgs []string
+}
+
+// Create creates and starts a new node named name on rt, the same
+// ContainerRuntime backend the returned Node will use for every subsequent
+// Cmder/Inspect/CopyTo/CopyFrom call. If rt is nil, runtime.Default() is
+// used, so a provider that only ever targets a single backend does not
+// need to resolve it itself.
+//
+// This is the create-path counterpart to SetContainerRuntime: without it,
+// Podman-only hosts could use a Node once one already existed, but had no
+// runtime-agnostic way to stand the container up in the first place.
+func Create(name string, rt runtime.ContainerRuntime, opts CreateOptions) (*Node, error) {
+	if rt == nil {
+		var err error
+		rt, err = runtime.Default()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	args := append(append([]string{}, opts.ExtraArgs...), opts.Image)
+	if err := rt.Create(name, args...); err != nil {
+		return nil, errors.Wrapf(err, "failed to create node %q", name)
+	}
+
+	n := &Node{name: name}
+	n.SetContainerRuntime(rt)
+
+	if err := rt.Start(name); err != nil {
+		return nil, errors.Wrapf(err, "failed to start node %q", name)
+	}
+
+	return n, nil
+}
+
+// Delete stops and removes the node's underlying container via its
+// container runtime backend
+func (n *Node) Delete() error {
+	rt, err := n.containerRuntime()
+	if err != nil {
+		return err
+	}
+	if err := rt.Stop(n.name); err != nil {
+		return errors.Wrapf(err, "failed to stop node %q", n.name)
+	}
+	if err := rt.Delete(n.name); err != nil {
+		return errors.Wrapf(err, "failed to delete node %q", n.name)
+	}
+	return nil
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package nodes
 
 import (
+	"archive/tar"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -29,17 +32,28 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 
-	"sigs.k8s.io/kind/pkg/container/docker"
+	"sigs.k8s.io/kind/pkg/container/runtime"
 	"sigs.k8s.io/kind/pkg/exec"
+
+	// side-effect imports to register the built-in container runtime
+	// backends with pkg/container/runtime; this is the only module-internal
+	// importer of either package, so without these blank imports their
+	// init() never runs and runtime.Default() can never resolve anything
+	_ "sigs.k8s.io/kind/pkg/container/docker"
+	_ "sigs.k8s.io/kind/pkg/container/podman"
 )
 
 // Node represents a handle to a kind node
-// This struct must be created by one of: CreateControlPlane
+// This struct must be created by one of: CreateControlPlane, Create
 // It should not be manually instantiated
 // Node impleemnts exec.Cmder
 type Node struct {
 	// must be one of docker container ID or name
 	name string
+	// the container runtime backend this node lives on, e.g. docker or
+	// podman. If nil, runtime.Default() is used, which defaults to docker
+	// but can be overridden with KIND_EXPERIMENTAL_PROVIDER.
+	runtime runtime.ContainerRuntime
 	// cached node info etc.
 	cache *nodeCache
 }
@@ -47,9 +61,40 @@ type Node struct {
 // assert Node implements Cmder
 var _ exec.Cmder = &Node{}
 
-// Cmder returns an exec.Cmder that runs on the node via docker exec
+// SetContainerRuntime pins this node to rt instead of runtime.Default().
+// This is the hook a cluster context's provider option should use to make
+// every node it creates use e.g. the Podman backend.
+func (n *Node) SetContainerRuntime(rt runtime.ContainerRuntime) {
+	n.runtime = rt
+}
+
+// containerRuntime returns the container runtime backend for this node,
+// falling back to runtime.Default() if none was set explicitly
+func (n *Node) containerRuntime() (runtime.ContainerRuntime, error) {
+	if n.runtime != nil {
+		return n.runtime, nil
+	}
+	return runtime.Default()
+}
+
+// mustContainerRuntime is like containerRuntime, but panics if no backend
+// is available. It exists only for methods that cannot return an error:
+// Command, whose signature is fixed by the exec.Cmder interface, and
+// Cmder, which Command is built on; a
+// misconfigured/unregistered runtime is an environment error the caller
+// cannot recover from anyway.
+func (n *Node) mustContainerRuntime() runtime.ContainerRuntime {
+	rt, err := n.containerRuntime()
+	if err != nil {
+		panic(err)
+	}
+	return rt
+}
+
+// Cmder returns an exec.Cmder that runs on the node via the node's
+// container runtime (e.g. `docker exec` or `podman exec`)
 func (n *Node) Cmder() exec.Cmder {
-	return docker.ContainerCmder(n.name)
+	return n.mustContainerRuntime().Cmder(n.name)
 }
 
 // Command returns a new exec.Cmd that will run on the node
@@ -112,15 +157,24 @@ func (n *Node) Name() string {
 
 // CopyTo copies the source file on the host to dest on the node
 func (n *Node) CopyTo(source, dest string) error {
-	return docker.CopyTo(source, n.name, dest)
+	rt, err := n.containerRuntime()
+	if err != nil {
+		return err
+	}
+	return rt.CopyTo(source, n.name, dest)
 }
 
 // CopyFrom copies the source file on the node to dest on the host
 // TODO(fabrizio pandini): note that this does have limitations around symlinks
-//     but this should go away when kubeadm automatic copy certs lands,
-//     otherwise it should be refactored in something more robust in the long term
+//
+//	but this should go away when kubeadm automatic copy certs lands,
+//	otherwise it should be refactored in something more robust in the long term
 func (n *Node) CopyFrom(source, dest string) error {
-	return docker.CopyFrom(n.name, source, dest)
+	rt, err := n.containerRuntime()
+	if err != nil {
+		return err
+	}
+	return rt.CopyFrom(n.name, source, dest)
 }
 
 // KubeVersion returns the Kubernetes version installed on the node
@@ -153,8 +207,12 @@ func (n *Node) IP() (ip string, err error) {
 	if cachedIP != "" {
 		return cachedIP, nil
 	}
-	// retrive the IP address of the node using docker inspect
-	lines, err := docker.Inspect(n.name, "{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}")
+	// retrive the IP address of the node using the container runtime's inspect
+	rt, err := n.containerRuntime()
+	if err != nil {
+		return "", err
+	}
+	lines, err := rt.Inspect(n.name, "{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}")
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get file")
 	}
@@ -177,8 +235,12 @@ func (n *Node) Ports(containerPort int32) (hostPort int32, err error) {
 	if isCached {
 		return hostPort, nil
 	}
-	// retrive the specific port mapping using docker inspect
-	lines, err := docker.Inspect(n.name, fmt.Sprintf("{{(index (index .NetworkSettings.Ports \"%d/tcp\") 0).HostPort}}", containerPort))
+	// retrive the specific port mapping using the container runtime's inspect
+	rt, err := n.containerRuntime()
+	if err != nil {
+		return -1, err
+	}
+	lines, err := rt.Inspect(n.name, fmt.Sprintf("{{(index (index .NetworkSettings.Ports \"%d/tcp\") 0).HostPort}}", containerPort))
 	if err != nil {
 		return -1, errors.Wrap(err, "failed to get file")
 	}
@@ -207,8 +269,12 @@ func (n *Node) Role() (role string, err error) {
 	if role != "" {
 		return role, nil
 	}
-	// retrive the role the node using docker inspect
-	lines, err := docker.Inspect(n.name, fmt.Sprintf("{{index .Config.Labels %q}}", constants.NodeRoleKey))
+	// retrive the role the node using the container runtime's inspect
+	rt, err := n.containerRuntime()
+	if err != nil {
+		return "", err
+	}
+	lines, err := rt.Inspect(n.name, fmt.Sprintf("{{index .Config.Labels %q}}", constants.NodeRoleKey))
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to get %q label", constants.NodeRoleKey)
 	}
@@ -246,28 +312,145 @@ func (n *Node) ImageInspect(containerNameOrID string) ([]string, error) {
 // k8s.io namespace on the node such that the image can be used from a
 // Kubernetes pod
 func (n *Node) LoadImageArchive(image io.Reader) error {
+	return n.LoadImageArchiveWithOptions(image, LoadImageArchiveOptions{})
+}
+
+// LoadImageArchiveOptions customizes the behavior of LoadImageArchiveWithOptions
+type LoadImageArchiveOptions struct {
+	// AdditionalRegistries re-tags each image in the archive, once imported,
+	// under these registry prefixes as well, preserving its original repo
+	// and tag. This lets a pod spec pulling from e.g. a mirrored
+	// system-default-registry (as used by RKE2/k3s) resolve to content that
+	// was actually loaded from a local archive.
+	AdditionalRegistries []string
+}
+
+// LoadImageArchiveWithOptions is like LoadImageArchive, but additionally
+// re-tags every image found in the archive's manifest.json under each of
+// opts.AdditionalRegistries
+//
+// manifest.json is conventionally written at (or near) the *end* of a
+// `docker save` tarball, after every layer blob, so it cannot be recovered
+// from a bounded prefix of the stream. To avoid holding a potentially
+// multi-gigabyte image in memory, we instead tee the archive to a spooled
+// temp file on disk as it streams to `ctr images import`, then make a
+// second, on-disk pass over the complete archive to read manifest.json
+// back out once the import has finished.
+func (n *Node) LoadImageArchiveWithOptions(image io.Reader, opts LoadImageArchiveOptions) error {
 	cmd := n.Command(
 		"ctr", "--namespace=k8s.io", "images", "import", "-",
 	)
-	cmd.SetStdin(image)
+
+	reader := image
+	var spool *os.File
+	if len(opts.AdditionalRegistries) > 0 {
+		var err error
+		spool, err = ioutil.TempFile("", "kind-image-archive-*.tar")
+		if err != nil {
+			return errors.Wrap(err, "failed to create temporary file for image archive")
+		}
+		defer os.Remove(spool.Name())
+		defer spool.Close()
+		reader = io.TeeReader(image, spool)
+	}
+	cmd.SetStdin(reader)
 	if err := cmd.Run(); err != nil {
 		return errors.Wrap(err, "failed to load image")
 	}
+
+	if spool == nil {
+		return nil
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, "failed to read back spooled image archive")
+	}
+	repoTags, err := readManifestRepoTags(spool)
+	if err != nil {
+		return errors.Wrap(err, "failed to read image archive manifest")
+	}
+
+	for _, repoTag := range repoTags {
+		repo, tag, err := splitRepoTag(repoTag)
+		if err != nil {
+			return err
+		}
+		for _, registry := range opts.AdditionalRegistries {
+			retagged := fmt.Sprintf("%s/%s:%s", strings.TrimSuffix(registry, "/"), repo, tag)
+			cmd := n.Command("ctr", "--namespace=k8s.io", "images", "tag", repoTag, retagged)
+			if err := exec.RunLoggingOutputOnFail(cmd); err != nil {
+				return errors.Wrapf(err, "failed to tag %s as %s", repoTag, retagged)
+			}
+		}
+	}
 	return nil
 }
 
-// proxyDetails contains proxy settings discovered on the host
-type proxyDetails struct {
+// imageArchiveManifest mirrors the subset of a `docker save` manifest.json
+// entry that we need to discover each image's original repo tags
+type imageArchiveManifest struct {
+	RepoTags []string `json:"RepoTags"`
+}
+
+// readManifestRepoTags scans archive as a tar stream looking for a
+// manifest.json entry, and returns the union of all RepoTags it declares
+func readManifestRepoTags(archive io.Reader) ([]string, error) {
+	tr := tar.NewReader(archive)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("manifest.json not found in image archive")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name != "manifest.json" {
+			continue
+		}
+		var manifests []imageArchiveManifest
+		if err := json.NewDecoder(tr).Decode(&manifests); err != nil {
+			return nil, errors.Wrap(err, "failed to decode manifest.json")
+		}
+		var repoTags []string
+		for _, m := range manifests {
+			repoTags = append(repoTags, m.RepoTags...)
+		}
+		return repoTags, nil
+	}
+}
+
+// splitRepoTag splits a docker "repo:tag" reference into its repo and tag,
+// tolerating registry hosts that themselves contain a port (host:port/repo:tag)
+func splitRepoTag(repoTag string) (repo, tag string, err error) {
+	i := strings.LastIndex(repoTag, ":")
+	if i < 0 || strings.Contains(repoTag[i+1:], "/") {
+		return "", "", errors.Errorf("invalid repo tag %q, expected repo:tag", repoTag)
+	}
+	return repoTag[:i], repoTag[i+1:], nil
+}
+
+// hostIntegration contains settings discovered on (or configured for) the
+// host that need to be threaded through to the nodes, such as proxy
+// environment variables and containerd registry mirrors/auth
+type hostIntegration struct {
+	// Envs are the proxy environment variables that should be passed to the nodes
 	Envs map[string]string
-	// future proxy details here
+	// Registries are the containerd registry mirrors/auth that should be
+	// configured on the nodes, as read from registryMirrorsEnvVar
+	Registries []RegistryMirror
 }
 
-// getProxyDetails returns a struct with the host environment proxy settings
-// that should be passed to the nodes
-func getProxyDetails() proxyDetails {
+// registryMirrorsEnvVar holds a JSON-encoded []RegistryMirror describing
+// the registry mirrors that should be configured on every node, until a
+// cluster config `registries:` field exists to set this instead
+const registryMirrorsEnvVar = "KIND_EXPERIMENTAL_REGISTRY_MIRRORS"
+
+// getProxyDetails returns a hostIntegration populated with the host
+// environment's proxy settings and any registry mirrors requested via
+// registryMirrorsEnvVar
+func getProxyDetails() hostIntegration {
 	var proxyEnvs = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"}
 	var val string
-	var details proxyDetails
+	var details hostIntegration
 	details.Envs = make(map[string]string)
 
 	for _, name := range proxyEnvs {
@@ -281,5 +464,22 @@ func getProxyDetails() proxyDetails {
 			}
 		}
 	}
+
+	if raw := os.Getenv(registryMirrorsEnvVar); raw != "" {
+		var mirrors []RegistryMirror
+		if err := json.Unmarshal([]byte(raw), &mirrors); err == nil {
+			details.Registries = mirrors
+		}
+	}
+
 	return details
 }
+
+// ApplyHostIntegration configures the node with the proxy-adjacent host
+// settings gathered by getProxyDetails, currently just registry mirrors
+// (see ConfigureRegistries). PullImage calls this before importing so the
+// node's containerd already trusts any configured mirrors;
+// LoadImageArchiveWithOptions does not call it.
+func (n *Node) ApplyHostIntegration() error {
+	return n.ConfigureRegistries(getProxyDetails().Registries)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSplitRepoTag(t *testing.T) {
+	cases := []struct {
+		repoTag string
+		repo    string
+		tag     string
+		wantErr bool
+	}{
+		{repoTag: "k8s.gcr.io/pause:3.2", repo: "k8s.gcr.io/pause", tag: "3.2"},
+		{repoTag: "registry.local:5000/k8s.gcr.io/pause:3.2", repo: "registry.local:5000/k8s.gcr.io/pause", tag: "3.2"},
+		{repoTag: "busybox:latest", repo: "busybox", tag: "latest"},
+		{repoTag: "busybox", wantErr: true},
+		{repoTag: "registry.local:5000/busybox", wantErr: true},
+	}
+	for _, c := range cases {
+		repo, tag, err := splitRepoTag(c.repoTag)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("splitRepoTag(%q): expected error, got repo=%q tag=%q", c.repoTag, repo, tag)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitRepoTag(%q): unexpected error: %v", c.repoTag, err)
+			continue
+		}
+		if repo != c.repo || tag != c.tag {
+			t.Errorf("splitRepoTag(%q) = (%q, %q), want (%q, %q)", c.repoTag, repo, tag, c.repo, c.tag)
+		}
+	}
+}
+
+// buildTestArchive writes a minimal tar archive with a dummy layer entry
+// preceding manifest.json, mirroring how `docker save` puts manifest.json
+// after the layer content rather than first
+func buildTestArchive(t *testing.T, repoTags []string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	layer := []byte("not a real layer, just padding")
+	if err := tw.WriteHeader(&tar.Header{Name: "deadbeef/layer.tar", Size: int64(len(layer))}); err != nil {
+		t.Fatalf("failed to write layer header: %v", err)
+	}
+	if _, err := tw.Write(layer); err != nil {
+		t.Fatalf("failed to write layer content: %v", err)
+	}
+
+	manifest, err := json.Marshal([]imageArchiveManifest{{RepoTags: repoTags}})
+	if err != nil {
+		t.Fatalf("failed to marshal test manifest: %v", err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "manifest.json", Size: int64(len(manifest))}); err != nil {
+		t.Fatalf("failed to write manifest header: %v", err)
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		t.Fatalf("failed to write manifest content: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %v", err)
+	}
+	return &buf
+}
+
+func TestReadManifestRepoTags(t *testing.T) {
+	want := []string{"k8s.gcr.io/pause:3.2"}
+	archive := buildTestArchive(t, want)
+
+	got, err := readManifestRepoTags(archive)
+	if err != nil {
+		t.Fatalf("readManifestRepoTags returned error: %v", err)
+	}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("readManifestRepoTags() = %v, want %v", got, want)
+	}
+}
+
+func TestReadManifestRepoTagsMissing(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "deadbeef/layer.tar", Size: 0}); err != nil {
+		t.Fatalf("failed to write layer header: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %v", err)
+	}
+
+	if _, err := readManifestRepoTags(&buf); err == nil {
+		t.Error("expected an error for an archive with no manifest.json, got nil")
+	}
+}
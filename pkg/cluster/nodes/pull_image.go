@@ -0,0 +1,168 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// pullLogger receives Node.PullImage's progress output. It defaults to
+// discarding everything; callers such as the CLI should call SetPullLogger
+// once at startup to see pull progress.
+var pullLogger log.Logger = log.NoopLogger
+
+// SetPullLogger sets the logger used to report Node.PullImage progress
+func SetPullLogger(l log.Logger) {
+	pullLogger = l
+}
+
+// PullImageOptions customizes the behavior of Node.PullImage
+type PullImageOptions struct {
+	// Platform selects a specific os/arch/variant from a multi-platform
+	// image, e.g. "linux/arm64". If empty, the registry's default manifest
+	// for the image is used.
+	Platform string
+	// RequireSignaturePresent, if set, requires that ref have a (legacy
+	// tag-based) signature manifest published alongside it before it is
+	// imported onto the node. This only checks that a signature exists; it
+	// does not cryptographically verify it against any key, and must not be
+	// treated as proof the image's contents are trusted.
+	RequireSignaturePresent bool
+}
+
+// PullImage fetches ref directly from its registry, using the host's
+// docker/podman credential keychain and honoring the host's proxy
+// environment, and imports it into the node's containerd. This avoids
+// needing the caller to `docker save` the image to a local tarball first.
+//
+// Note: a `kind load image --pull` CLI flag to expose this from the
+// command line has not been wired up yet; today PullImage is only
+// reachable by calling it directly.
+func (n *Node) PullImage(ref string, opts PullImageOptions) error {
+	nameRef, err := name.ParseReference(ref)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse image reference %q", ref)
+	}
+
+	if err := n.ApplyHostIntegration(); err != nil {
+		return errors.Wrap(err, "failed to apply host registry configuration")
+	}
+
+	remoteOpts := []remote.Option{
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithTransport(proxyAwareTransport(getProxyDetails())),
+	}
+	if opts.Platform != "" {
+		platform, err := v1.ParsePlatform(opts.Platform)
+		if err != nil {
+			return errors.Wrapf(err, "invalid platform %q", opts.Platform)
+		}
+		remoteOpts = append(remoteOpts, remote.WithPlatform(*platform))
+	}
+
+	if opts.RequireSignaturePresent {
+		if err := checkSignaturePresence(nameRef, remoteOpts); err != nil {
+			return errors.Wrapf(err, "no signature found for %q", ref)
+		}
+	}
+
+	img, err := remote.Image(nameRef, remoteOpts...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to pull %q", ref)
+	}
+
+	pr, pw := io.Pipe()
+	cmd := n.Command("ctr", "--namespace=k8s.io", "images", "import", "-")
+	cmd.SetStdin(pr)
+
+	importDone := make(chan error, 1)
+	go func() {
+		importDone <- cmd.Run()
+	}()
+
+	// stream actual write progress as tarball.Write serializes img's layers,
+	// rather than guessing at progress before any bytes have moved
+	updates := make(chan v1.Update, 1)
+	go func() {
+		for u := range updates {
+			if u.Error != nil {
+				continue
+			}
+			pullLogger.Infof("%s: pulling %s - %d/%d bytes", n.name, ref, u.Complete, u.Total)
+		}
+	}()
+
+	writeErr := tarball.Write(nameRef, img, pw, tarball.WithProgress(updates))
+	_ = pw.CloseWithError(writeErr)
+
+	if err := <-importDone; err != nil {
+		return errors.Wrapf(err, "failed to import %q", ref)
+	}
+	if writeErr != nil {
+		return errors.Wrapf(writeErr, "failed to stream %q to node", ref)
+	}
+	return nil
+}
+
+// proxyAwareTransport returns an http.RoundTripper that routes registry
+// requests through the proxy settings kind already gathers for the nodes,
+// falling back to the process's own proxy environment
+func proxyAwareTransport(proxy hostIntegration) http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		scheme := strings.ToUpper(req.URL.Scheme) + "_PROXY"
+		if p, ok := proxy.Envs[scheme]; ok {
+			return url.Parse(p)
+		}
+		return http.ProxyFromEnvironment(req)
+	}
+	return transport
+}
+
+// checkSignaturePresence checks for the presence of a cosign-style
+// tag-based signature manifest (<repo>:<digest-with-dashes>.sig) for ref,
+// and returns an error if none is found. This is a presence check only: it
+// does not fetch or cryptographically validate the signature against any
+// key, so it must not be relied on as proof of authenticity by itself.
+func checkSignaturePresence(ref name.Reference, opts []remote.Option) error {
+	desc, err := remote.Get(ref, opts...)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve image digest")
+	}
+	sigTagName := fmt.Sprintf("%s:%s.sig", ref.Context().Name(), strings.ReplaceAll(desc.Digest.String(), ":", "-"))
+	sigTag, err := name.NewTag(sigTagName, name.WeakValidation)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build signature tag for %s", ref)
+	}
+	if _, err := remote.Head(sigTag, opts...); err != nil {
+		return errors.Errorf("no signature manifest found at %s", sigTag)
+	}
+	return nil
+}
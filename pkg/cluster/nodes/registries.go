@@ -0,0 +1,169 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// RegistryMirror describes a containerd registry mirror (and optional auth)
+// that should be configured on a node, one entry of the JSON list read from
+// registryMirrorsEnvVar; the kind cluster config has no `registries:` field
+type RegistryMirror struct {
+	// Host is the registry hostname (optionally host:port) being mirrored,
+	// e.g. "docker.io" or "registry.local:5000"
+	Host string
+	// Endpoints lists the mirror servers to try, in order, before falling
+	// back to Host itself. Each entry may already include a scheme (e.g.
+	// "http://mirror.local:5000"); if it does not, Insecure determines
+	// whether "http://" or "https://" is assumed.
+	Endpoints []string
+	// CACert is a PEM-encoded CA certificate used to validate Endpoints, if
+	// they serve a certificate not already trusted by the node
+	CACert string
+	// SkipVerify disables TLS certificate verification for Endpoints
+	SkipVerify bool
+	// Insecure allows plain HTTP for Endpoints and Host, and disables TLS
+	// certificate verification
+	Insecure bool
+	// AuthHeader, if set, is sent as the Authorization header on every
+	// request to Endpoints, e.g. "Basic <base64>"
+	AuthHeader string
+}
+
+// containerdCertsDir is where containerd looks for per-host hosts.toml
+// drop-ins once config_path is set in its config.toml
+const containerdCertsDir = "/etc/containerd/certs.d"
+
+// ConfigureRegistries writes a containerd 1.x config_path drop-in tree
+// under containerdCertsDir on the node for each mirror, ensures
+// config.toml actually points containerd at that directory, and restarts
+// containerd only if config.toml had to be changed. This lets users
+// declare private registry mirrors, insecure-registry allowances, and pull
+// credentials once (see registryMirrorsEnvVar) instead of editing
+// /etc/containerd/config.toml by hand on every node.
+func (n *Node) ConfigureRegistries(mirrors []RegistryMirror) error {
+	if len(mirrors) == 0 {
+		return nil
+	}
+
+	changed, err := n.ensureContainerdCertsDirConfigured()
+	if err != nil {
+		return errors.Wrap(err, "failed to configure containerd config_path")
+	}
+
+	for _, mirror := range mirrors {
+		dest := fmt.Sprintf("%s/%s/hosts.toml", containerdCertsDir, mirror.Host)
+		if err := n.WriteFile(dest, hostsToml(mirror)); err != nil {
+			return errors.Wrapf(err, "failed to configure registry mirror for %q", mirror.Host)
+		}
+	}
+
+	// hosts.toml contents are re-read by containerd on every pull, but
+	// config_path only takes effect once containerd (re)reads config.toml,
+	// so only restart if we actually had to change it
+	if !changed {
+		return nil
+	}
+	if err := n.Command("systemctl", "restart", "containerd").Run(); err != nil {
+		return errors.Wrap(err, "failed to restart containerd")
+	}
+	return nil
+}
+
+// ensureContainerdCertsDirConfigured idempotently points containerd's
+// config.toml at containerdCertsDir via config_path, reporting whether it
+// had to change the file. Without this, the hosts.toml files written by
+// ConfigureRegistries are silently ignored.
+//
+// kind node images already define a
+// `[plugins."io.containerd.grpc.v1.cri".registry]` table (for `mirrors`
+// entries from older configs), so we cannot simply append a fresh one:
+// containerd fails to parse config.toml if the table is defined twice. If
+// the table already exists, config_path is inserted into it by line number;
+// only if the table is entirely absent is a new one appended.
+func (n *Node) ensureContainerdCertsDirConfigured() (changed bool, err error) {
+	const registryTable = `[plugins."io.containerd.grpc.v1.cri".registry]`
+	configPathLine := fmt.Sprintf(`  config_path = "%s"`, containerdCertsDir)
+	// inserting via awk (rather than sed's "a" command) sidesteps having to
+	// escape configPathLine for use as a sed replacement/append argument
+	script := fmt.Sprintf(
+		`set -eu
+cfg=/etc/containerd/config.toml
+if grep -q 'config_path[[:space:]]*=[[:space:]]*"%[1]s"' "$cfg" 2>/dev/null; then
+  exit 0
+fi
+line=$(grep -nF '%[2]s' "$cfg" 2>/dev/null | head -1 | cut -d: -f1)
+if [ -n "$line" ]; then
+  awk -v n="$line" -v text='%[3]s' 'NR==n{print; print text; next}1' "$cfg" > "$cfg.kind-tmp"
+  mv "$cfg.kind-tmp" "$cfg"
+else
+  printf '\n%[2]s\n%[3]s\n' >> "$cfg"
+fi
+echo CHANGED
+`,
+		containerdCertsDir, registryTable, configPathLine,
+	)
+	out, err := exec.CombinedOutputLines(n.Command("sh", "-c", script))
+	if err != nil {
+		return false, err
+	}
+	return len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "CHANGED", nil
+}
+
+// hostsToml renders mirror as a containerd certs.d hosts.toml document, see
+// https://github.com/containerd/containerd/blob/main/docs/hosts.md
+func hostsToml(mirror RegistryMirror) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "server = %q\n\n", withScheme(mirror.Host, mirror.Insecure))
+	for _, endpoint := range mirror.Endpoints {
+		endpointURL := withScheme(endpoint, mirror.Insecure)
+		fmt.Fprintf(&b, "[host.%q]\n", endpointURL)
+		fmt.Fprintf(&b, "  capabilities = [\"pull\", \"resolve\"]\n")
+		if mirror.Insecure || mirror.SkipVerify {
+			fmt.Fprintf(&b, "  skip_verify = true\n")
+		}
+		if mirror.CACert != "" {
+			fmt.Fprintf(&b, "  ca = %q\n", mirror.CACert)
+		}
+		if mirror.AuthHeader != "" {
+			fmt.Fprintf(&b, "  [host.%q.header]\n", endpointURL)
+			fmt.Fprintf(&b, "    Authorization = %q\n", mirror.AuthHeader)
+		}
+	}
+	return b.String()
+}
+
+// withScheme returns hostOrURL unchanged if it already has a scheme,
+// otherwise prepends "http://" (if insecure) or "https://". containerd
+// requires host/server keys in hosts.toml to be full URLs; a bare
+// "host:port" is silently rejected.
+func withScheme(hostOrURL string, insecure bool) string {
+	if strings.Contains(hostOrURL, "://") {
+		return hostOrURL
+	}
+	scheme := "https"
+	if insecure {
+		scheme = "http"
+	}
+	return scheme + "://" + hostOrURL
+}
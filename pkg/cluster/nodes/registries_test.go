@@ -0,0 +1,86 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithScheme(t *testing.T) {
+	cases := []struct {
+		hostOrURL string
+		insecure  bool
+		want      string
+	}{
+		{hostOrURL: "registry.local:5000", insecure: false, want: "https://registry.local:5000"},
+		{hostOrURL: "registry.local:5000", insecure: true, want: "http://registry.local:5000"},
+		{hostOrURL: "http://mirror.local:5000", insecure: false, want: "http://mirror.local:5000"},
+		{hostOrURL: "https://mirror.local", insecure: true, want: "https://mirror.local"},
+	}
+	for _, c := range cases {
+		if got := withScheme(c.hostOrURL, c.insecure); got != c.want {
+			t.Errorf("withScheme(%q, %v) = %q, want %q", c.hostOrURL, c.insecure, got, c.want)
+		}
+	}
+}
+
+func TestHostsToml(t *testing.T) {
+	mirror := RegistryMirror{
+		Host:       "registry.local:5000",
+		Endpoints:  []string{"mirror.local:5000"},
+		CACert:     "-----BEGIN CERTIFICATE-----\n...\n-----END CERTIFICATE-----",
+		Insecure:   false,
+		AuthHeader: "Basic dXNlcjpwYXNz",
+	}
+	got := hostsToml(mirror)
+
+	for _, want := range []string{
+		`server = "https://registry.local:5000"`,
+		`[host."https://mirror.local:5000"]`,
+		`capabilities = ["pull", "resolve"]`,
+		`ca = "-----BEGIN CERTIFICATE-----`,
+		`[host."https://mirror.local:5000".header]`,
+		`Authorization = "Basic dXNlcjpwYXNz"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("hostsToml() missing expected line %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "skip_verify") {
+		t.Errorf("hostsToml() should not set skip_verify when Insecure and SkipVerify are both false, got:\n%s", got)
+	}
+}
+
+func TestHostsTomlInsecure(t *testing.T) {
+	mirror := RegistryMirror{
+		Host:      "registry.local:5000",
+		Endpoints: []string{"mirror.local:5000"},
+		Insecure:  true,
+	}
+	got := hostsToml(mirror)
+
+	for _, want := range []string{
+		`server = "http://registry.local:5000"`,
+		`[host."http://mirror.local:5000"]`,
+		`skip_verify = true`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("hostsToml() missing expected line %q, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"sigs.k8s.io/kind/pkg/container/runtime"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// Runtime implements runtime.ContainerRuntime backed by the docker CLI and
+// daemon. It is a thin adapter over the package-level helpers in this
+// package so that callers such as pkg/cluster/nodes can be written against
+// the ContainerRuntime interface instead of this package directly.
+type Runtime struct{}
+
+// assert Runtime implements ContainerRuntime
+var _ runtime.ContainerRuntime = &Runtime{}
+
+func init() {
+	runtime.Register(runtime.Docker, &Runtime{})
+}
+
+// Cmder returns an exec.Cmder that runs on containerName via `docker exec`
+func (r *Runtime) Cmder(containerName string) exec.Cmder {
+	return ContainerCmder(containerName)
+}
+
+// Inspect returns the result of `docker inspect -f format containerNameOrID`
+func (r *Runtime) Inspect(containerNameOrID, format string) ([]string, error) {
+	return Inspect(containerNameOrID, format)
+}
+
+// CopyTo copies hostSource to containerDest inside containerName via `docker cp`
+func (r *Runtime) CopyTo(hostSource, containerName, containerDest string) error {
+	return CopyTo(hostSource, containerName, containerDest)
+}
+
+// CopyFrom copies containerSource inside containerName to hostDest via `docker cp`
+func (r *Runtime) CopyFrom(containerName, containerSource, hostDest string) error {
+	return CopyFrom(containerName, containerSource, hostDest)
+}
+
+// Create creates (but does not start) a new node container with `docker create`
+func (r *Runtime) Create(name string, args ...string) error {
+	cmd := exec.Command("docker", append([]string{"create", "--name", name}, args...)...)
+	return exec.RunLoggingOutputOnFail(cmd)
+}
+
+// Start starts a previously created node container with `docker start`
+func (r *Runtime) Start(name string) error {
+	return exec.RunLoggingOutputOnFail(exec.Command("docker", "start", name))
+}
+
+// Stop stops a running node container with `docker stop`
+func (r *Runtime) Stop(name string) error {
+	return exec.RunLoggingOutputOnFail(exec.Command("docker", "stop", name))
+}
+
+// Delete removes a node container with `docker rm`
+func (r *Runtime) Delete(name string) error {
+	return exec.RunLoggingOutputOnFail(exec.Command("docker", "rm", "-f", "-v", name))
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package podman implements the runtime.ContainerRuntime interface on top
+// of the (rootless-capable) podman CLI, for hosts that have no docker
+// daemon available, e.g. RHEL/CentOS/Fedora where podman is the default
+// container engine.
+package podman
+
+import (
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/container/runtime"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// Runtime implements runtime.ContainerRuntime backed by the podman CLI
+type Runtime struct{}
+
+// assert Runtime implements ContainerRuntime
+var _ runtime.ContainerRuntime = &Runtime{}
+
+func init() {
+	runtime.Register(runtime.Podman, &Runtime{})
+}
+
+// containerCmder implements exec.Cmder for commands run via `podman exec`
+type containerCmder struct {
+	nameOrID string
+}
+
+// ContainerCmder returns an exec.Cmder that runs on containerNameOrID via
+// `podman exec`
+func ContainerCmder(containerNameOrID string) exec.Cmder {
+	return &containerCmder{nameOrID: containerNameOrID}
+}
+
+func (c *containerCmder) Command(command string, args ...string) exec.Cmd {
+	return exec.Command(
+		"podman",
+		append([]string{"exec", "-i", c.nameOrID, command}, args...)...,
+	)
+}
+
+// Cmder returns an exec.Cmder that runs on containerName via `podman exec`
+func (r *Runtime) Cmder(containerName string) exec.Cmder {
+	return ContainerCmder(containerName)
+}
+
+// Inspect returns the result of `podman inspect -f format containerNameOrID`
+func Inspect(containerNameOrID, format string) ([]string, error) {
+	cmd := exec.Command("podman", "inspect", "-f", format, containerNameOrID)
+	lines, err := exec.CombinedOutputLines(cmd)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to inspect container")
+	}
+	return lines, nil
+}
+
+// Inspect returns the result of `podman inspect -f format containerNameOrID`
+func (r *Runtime) Inspect(containerNameOrID, format string) ([]string, error) {
+	return Inspect(containerNameOrID, format)
+}
+
+// CopyTo copies source on the host into dest inside containerName via `podman cp`
+func CopyTo(source, containerName, dest string) error {
+	cmd := exec.Command("podman", "cp", source, containerName+":"+dest)
+	return errors.Wrap(exec.RunLoggingOutputOnFail(cmd), "failed to copy file to container")
+}
+
+// CopyTo copies source on the host into dest inside containerName via `podman cp`
+func (r *Runtime) CopyTo(source, containerName, dest string) error {
+	return CopyTo(source, containerName, dest)
+}
+
+// CopyFrom copies source inside containerName to dest on the host via `podman cp`
+func CopyFrom(containerName, source, dest string) error {
+	cmd := exec.Command("podman", "cp", containerName+":"+source, dest)
+	return errors.Wrap(exec.RunLoggingOutputOnFail(cmd), "failed to copy file from container")
+}
+
+// CopyFrom copies source inside containerName to dest on the host via `podman cp`
+func (r *Runtime) CopyFrom(containerName, source, dest string) error {
+	return CopyFrom(containerName, source, dest)
+}
+
+// Create creates (but does not start) a new node container with `podman create`
+func (r *Runtime) Create(name string, args ...string) error {
+	cmd := exec.Command("podman", append([]string{"create", "--name", name}, args...)...)
+	return exec.RunLoggingOutputOnFail(cmd)
+}
+
+// Start starts a previously created node container with `podman start`
+func (r *Runtime) Start(name string) error {
+	return exec.RunLoggingOutputOnFail(exec.Command("podman", "start", name))
+}
+
+// Stop stops a running node container with `podman stop`
+func (r *Runtime) Stop(name string) error {
+	return exec.RunLoggingOutputOnFail(exec.Command("podman", "stop", name))
+}
+
+// Delete removes a node container with `podman rm`
+func (r *Runtime) Delete(name string) error {
+	return exec.RunLoggingOutputOnFail(exec.Command("podman", "rm", "-f", "-v", name))
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package runtime defines the pluggable container runtime backend that
+// kind uses to create and manage node "containers", so that callers such
+// as pkg/cluster/nodes do not need to hard-code against a particular
+// container engine.
+package runtime
+
+import (
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// Name identifies a registered ContainerRuntime backend
+type Name string
+
+const (
+	// Docker is the default container runtime backend, talking to a local
+	// docker daemon
+	Docker Name = "docker"
+	// Podman is a rootless-capable alternative backend, talking to podman
+	Podman Name = "podman"
+)
+
+// EnvVar is the environment variable used to select a non-default backend,
+// e.g. KIND_EXPERIMENTAL_PROVIDER=podman
+const EnvVar = "KIND_EXPERIMENTAL_PROVIDER"
+
+// ContainerRuntime abstracts the container engine used to create, inspect,
+// and interact with kind's nodes. Implementations should be registered with
+// Register from an init() function in their package.
+type ContainerRuntime interface {
+	// Cmder returns an exec.Cmder that runs commands inside the named
+	// container, equivalent to `docker exec`
+	Cmder(containerName string) exec.Cmder
+	// Inspect returns the result of formatting containerNameOrID with
+	// format, one result per matching line, equivalent to
+	// `docker inspect -f format containerNameOrID`
+	Inspect(containerNameOrID, format string) ([]string, error)
+	// CopyTo copies the file at hostSource on the host to containerDest
+	// inside containerName
+	CopyTo(hostSource, containerName, containerDest string) error
+	// CopyFrom copies the file at containerSource inside containerName to
+	// hostDest on the host
+	CopyFrom(containerName, containerSource, hostDest string) error
+	// Create creates (but does not start) a new node container named name
+	Create(name string, args ...string) error
+	// Start starts a previously created node container
+	Start(name string) error
+	// Stop stops a running node container
+	Stop(name string) error
+	// Delete removes a node container
+	Delete(name string) error
+}
+
+var (
+	mu       sync.RWMutex
+	backends = map[Name]ContainerRuntime{}
+)
+
+// Register makes a ContainerRuntime backend available under name. It is
+// meant to be called from the init() function of the backend's package.
+func Register(name Name, runtime ContainerRuntime) {
+	mu.Lock()
+	defer mu.Unlock()
+	backends[name] = runtime
+}
+
+// Get returns the backend registered under name, if any
+func Get(name Name) (ContainerRuntime, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	runtime, ok := backends[name]
+	return runtime, ok
+}
+
+// Default returns the container runtime backend selected via the EnvVar
+// environment variable, falling back to Docker if it is unset. It returns
+// ErrNotRegistered rather than a nil ContainerRuntime if the selected
+// backend (including Docker itself) was never registered, e.g. because its
+// package was not imported for side effects.
+func Default() (ContainerRuntime, error) {
+	name := Name(os.Getenv(EnvVar))
+	if name == "" {
+		name = Docker
+	}
+	selected, ok := Get(name)
+	if !ok {
+		return nil, ErrNotRegistered(name)
+	}
+	return selected, nil
+}
+
+// ErrNotRegistered is returned by callers that require a specific backend
+// by name and find that it has not been registered (e.g. built without its
+// package imported for side effects)
+func ErrNotRegistered(name Name) error {
+	return errors.Errorf("container runtime %q is not registered", name)
+}
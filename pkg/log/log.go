@@ -0,0 +1,36 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package log defines the minimal logging interface shared across kind's
+// internal packages, so that a CLI (or any other caller) can plug in
+// whatever logger implementation it likes (e.g. a spinner-backed logger)
+// without pkg/cluster/nodes writing straight to stderr.
+package log
+
+// Logger is the logging interface consumed by kind's internal packages
+type Logger interface {
+	// Infof logs a progress/status message
+	Infof(format string, args ...interface{})
+}
+
+// noopLogger is a Logger that discards everything
+type noopLogger struct{}
+
+func (noopLogger) Infof(format string, args ...interface{}) {}
+
+// NoopLogger is the default Logger used by packages that were never given
+// an explicit one
+var NoopLogger Logger = noopLogger{}